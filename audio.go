@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/mp3"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+	"io"
+	"log"
+)
+
+const sampleRate = 44100
+
+var masterVolume = flag.Float64("volume", 1.0, "master volume (0.0-1.0)")
+
+// soundPool is how many audio.Players back a single clip. Rapid-fire
+// weapons can trigger the same sound several times before the first
+// instance finishes, so each Play picks whichever pooled player is free
+// instead of allocating a new one.
+const soundPool = 8
+
+// SoundManager decodes each sound effect once at startup and plays it back
+// through a small pool of reusable audio.Players, so once a clip's pool has
+// grown, firing a weapon reuses those players instead of allocating.
+type SoundManager struct {
+	ctx    *audio.Context
+	clips  map[string][]byte
+	pools  map[string][]*audio.Player
+	volume float64
+}
+
+func NewSoundManager() *SoundManager {
+	return &SoundManager{
+		ctx:    audio.NewContext(sampleRate),
+		clips:  make(map[string][]byte),
+		pools:  make(map[string][]*audio.Player),
+		volume: *masterVolume,
+	}
+}
+
+// LoadWAV decodes a wav asset and registers it under name.
+func (sm *SoundManager) LoadWAV(name, path string) {
+	sm.clips[name] = sm.decode(path, func(r io.Reader) (io.Reader, error) {
+		return wav.DecodeWithoutResampling(r)
+	})
+}
+
+// LoadMP3 decodes an mp3 asset and registers it under name.
+func (sm *SoundManager) LoadMP3(name, path string) {
+	sm.clips[name] = sm.decode(path, func(r io.Reader) (io.Reader, error) {
+		return mp3.DecodeWithoutResampling(r)
+	})
+}
+
+func (sm *SoundManager) decode(path string, decode func(io.Reader) (io.Reader, error)) []byte {
+	f, err := assets.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	stream, err := decode(f)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	raw, err := io.ReadAll(stream)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return raw
+}
+
+// Play fires name once, picking a free player from the pool (or growing
+// the pool up to soundPool) rather than allocating a new one every call.
+func (sm *SoundManager) Play(name string) {
+	clip, ok := sm.clips[name]
+	if !ok {
+		return
+	}
+
+	pool := sm.pools[name]
+	for _, p := range pool {
+		if !p.IsPlaying() {
+			p.Rewind()
+			p.SetVolume(sm.volume)
+			p.Play()
+			return
+		}
+	}
+
+	if len(pool) >= soundPool {
+		return
+	}
+
+	p := audio.NewPlayerFromBytes(sm.ctx, clip)
+	p.SetVolume(sm.volume)
+	p.Play()
+	sm.pools[name] = append(pool, p)
+}
+
+// PlayMusic starts name looping forever; call once for background music.
+func (sm *SoundManager) PlayMusic(name string) {
+	clip, ok := sm.clips[name]
+	if !ok {
+		return
+	}
+
+	loop := audio.NewInfiniteLoop(bytes.NewReader(clip), int64(len(clip)))
+	p, err := audio.NewPlayer(sm.ctx, loop)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	p.SetVolume(sm.volume)
+	p.Play()
+}
+
+func (sm *SoundManager) SetMasterVolume(v float64) {
+	sm.volume = v
+}
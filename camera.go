@@ -0,0 +1,92 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	camScaleMin  = 0.3
+	camScaleMax  = 3.0
+	camLerpSpeed = 0.2
+)
+
+// cameraUpdate handles mouse-wheel zoom and middle-click pan, and smoothly
+// interpolates camScale toward camScaleTo so zooming doesn't snap.
+func (g *Game) cameraUpdate() {
+	_, wheelY := ebiten.Wheel()
+	if wheelY != 0 {
+		g.camScaleTo += wheelY * 0.1
+		if g.camScaleTo < camScaleMin {
+			g.camScaleTo = camScaleMin
+		}
+		if g.camScaleTo > camScaleMax {
+			g.camScaleTo = camScaleMax
+		}
+	}
+	g.camScale += (g.camScaleTo - g.camScale) * camLerpSpeed
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonMiddle) {
+		g.mousePanX, g.mousePanY = ebiten.CursorPosition()
+	}
+	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonMiddle) {
+		x, y := ebiten.CursorPosition()
+		g.camOffset.X -= float64(x-g.mousePanX) / g.camScale
+		g.camOffset.Y -= float64(y-g.mousePanY) / g.camScale
+		g.mousePanX, g.mousePanY = x, y
+	}
+}
+
+// camCenter is the world position the camera is currently looking at: the
+// player, nudged by any manual pan offset.
+func (g *Game) camCenter() Vector {
+	bounds := g.player.sprite.Bounds()
+	return Vector{
+		X: g.player.position.X + float64(bounds.Dx())/2 + g.camOffset.X,
+		Y: g.player.position.Y + float64(bounds.Dy())/2 + g.camOffset.Y,
+	}
+}
+
+// camGeoM maps world coordinates onto the screen: center the camera, scale
+// by the current zoom, then center on the viewport.
+func (g *Game) camGeoM() ebiten.GeoM {
+	center := g.camCenter()
+
+	m := ebiten.GeoM{}
+	m.Translate(-center.X, -center.Y)
+	m.Scale(g.camScale, g.camScale)
+	m.Translate(float64(g.screenWidth)/2, float64(g.screenHeight)/2)
+	return m
+}
+
+// camFrustum returns the half-width and half-height, in world units, of
+// what's currently visible through the camera.
+func (g *Game) camFrustum() (float64, float64) {
+	return float64(g.screenWidth) / 2 / g.camScale, float64(g.screenHeight) / 2 / g.camScale
+}
+
+var BackgroundTile = mustLoadImage("assets/background/tile.png")
+
+// drawBackground tiles BackgroundTile across whatever part of the world is
+// currently visible through cam.
+func (g *Game) drawBackground(screen *ebiten.Image, cam ebiten.GeoM) {
+	bounds := BackgroundTile.Bounds()
+	tw, th := float64(bounds.Dx()), float64(bounds.Dy())
+
+	center := g.camCenter()
+	halfW, halfH := g.camFrustum()
+
+	startX := int(center.X-halfW)/int(tw) - 1
+	endX := int(center.X+halfW)/int(tw) + 1
+	startY := int(center.Y-halfH)/int(th) - 1
+	endY := int(center.Y+halfH)/int(th) + 1
+
+	for ty := startY; ty <= endY; ty++ {
+		for tx := startX; tx <= endX; tx++ {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(float64(tx)*tw, float64(ty)*th)
+			op.GeoM.Concat(cam)
+			screen.DrawImage(BackgroundTile, op)
+		}
+	}
+}
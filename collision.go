@@ -0,0 +1,102 @@
+package main
+
+import "math"
+
+// Entity is anything that can be placed in a CollisionSpace.
+type Entity interface {
+	Collider() Rect
+}
+
+type cellKey struct {
+	x, y int
+}
+
+// CollisionSpace is a uniform grid spatial hash. Cells are cellSize world
+// units square; an entity is inserted into every cell its Collider
+// overlaps, so Query only has to look at the handful of cells around the
+// area being tested instead of every entity in the scene.
+type CollisionSpace struct {
+	cellSize float64
+	cells    map[cellKey][]Entity
+	cellsOf  map[Entity][]cellKey
+}
+
+func NewCollisionSpace(cellSize float64) *CollisionSpace {
+	return &CollisionSpace{
+		cellSize: cellSize,
+		cells:    make(map[cellKey][]Entity),
+		cellsOf:  make(map[Entity][]cellKey),
+	}
+}
+
+func (cs *CollisionSpace) keysFor(r Rect) []cellKey {
+	minX := int(math.Floor(r.X / cs.cellSize))
+	minY := int(math.Floor(r.Y / cs.cellSize))
+	maxX := int(math.Floor(r.MaxX() / cs.cellSize))
+	maxY := int(math.Floor(r.MaxY() / cs.cellSize))
+
+	var keys []cellKey
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			keys = append(keys, cellKey{x, y})
+		}
+	}
+	return keys
+}
+
+func (cs *CollisionSpace) Insert(e Entity) {
+	keys := cs.keysFor(e.Collider())
+	cs.cellsOf[e] = keys
+
+	for _, k := range keys {
+		cs.cells[k] = append(cs.cells[k], e)
+	}
+}
+
+func (cs *CollisionSpace) Remove(e Entity) {
+	for _, k := range cs.cellsOf[e] {
+		bucket := cs.cells[k]
+		for i, other := range bucket {
+			if other == e {
+				cs.cells[k] = append(bucket[:i], bucket[i+1:]...)
+				break
+			}
+		}
+	}
+	delete(cs.cellsOf, e)
+}
+
+// Rebuild clears the grid and re-inserts every entity; Game.Update calls it
+// each tick (and again after removing dead enemies) rather than keeping
+// Insert/Remove in sync with every actor move.
+func (cs *CollisionSpace) Rebuild(entities []Entity) {
+	for k := range cs.cells {
+		delete(cs.cells, k)
+	}
+	for e := range cs.cellsOf {
+		delete(cs.cellsOf, e)
+	}
+
+	for _, e := range entities {
+		cs.Insert(e)
+	}
+}
+
+// Query returns every entity sharing a cell with r, deduplicated. Callers
+// still need to check Rect.Intersects themselves, since sharing a cell only
+// means "nearby", not "overlapping".
+func (cs *CollisionSpace) Query(r Rect) []Entity {
+	seen := make(map[Entity]bool)
+	var results []Entity
+
+	for _, k := range cs.keysFor(r) {
+		for _, e := range cs.cells[k] {
+			if !seen[e] {
+				seen[e] = true
+				results = append(results, e)
+			}
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+)
+
+// angle returns the angle in radians from the point (x2, y2) to (x1, y1).
+func angle(x1, y1, x2, y2 float64) float64 {
+	return math.Atan2(y1-y2, x1-x2)
+}
+
+// deltaXY returns the X and Y components of a unit vector pointing at the
+// given angle, scaled by speed.
+func deltaXY(a, speed float64) (float64, float64) {
+	return math.Cos(a) * speed, math.Sin(a) * speed
+}
+
+type enemyState int
+
+const (
+	enemyStateWander enemyState = iota
+	enemyStateSeek
+	enemyStateFlee
+	enemyStateOrbit
+)
+
+const (
+	minSpeed     = 0.25
+	maxSpeed     = 3.0
+	seekDistance = 250.0
+	fleeDistance = 40.0
+	orbitRadius  = 150.0
+)
+
+// Enemy wraps a Meteor with a small state machine driving its movement, so
+// the same sprite set can wander, chase the player, flee from bullets, or
+// orbit around the player depending on its current state.
+type Enemy struct {
+	*Meteor
+	state      enemyState
+	nextAction int
+	hp         int
+}
+
+// enemyHP is how many points of bullet damage an enemy can soak up before
+// it dies.
+const enemyHP = 3
+
+// NewEnemy spawns an enemy just outside (target, r) — typically the
+// player's camera frustum — starting out in the wander state.
+func NewEnemy(target Vector, r float64) *Enemy {
+	return &Enemy{
+		Meteor:     NewMeteor(target, r),
+		state:      enemyStateWander,
+		nextAction: 60 + rand.Intn(120),
+		hp:         enemyHP,
+	}
+}
+
+// clampSpeed scales the movement vector by 1.1 or 0.9 repeatedly until its
+// magnitude falls back between minSpeed and maxSpeed.
+func clampSpeed(v Vector) Vector {
+	for {
+		speed := math.Sqrt(v.X*v.X + v.Y*v.Y)
+		if speed < minSpeed {
+			v.X *= 1.1
+			v.Y *= 1.1
+			continue
+		}
+		if speed > maxSpeed {
+			v.X *= 0.9
+			v.Y *= 0.9
+			continue
+		}
+		return v
+	}
+}
+
+func (e *Enemy) Update(player *Player, bullets []*Bullet) error {
+	e.nextAction--
+
+	dx := e.Position.X - player.position.X
+	dy := e.Position.Y - player.position.Y
+	distToPlayer := math.Sqrt(dx*dx + dy*dy)
+
+	switch e.state {
+	case enemyStateWander, enemyStateOrbit:
+		if distToPlayer <= seekDistance || rand.Intn(66) == 0 {
+			e.state = enemyStateSeek
+		}
+	}
+
+	// A nearby bullet spooks the enemy into fleeing for twice as long as its
+	// current action countdown.
+	for _, b := range bullets {
+		bdx := e.Position.X - b.position.X
+		bdy := e.Position.Y - b.position.Y
+		if math.Sqrt(bdx*bdx+bdy*bdy) < fleeDistance {
+			if e.state != enemyStateFlee {
+				e.state = enemyStateFlee
+				e.nextAction = e.nextAction * 2
+			}
+			break
+		}
+	}
+
+	if e.nextAction <= 0 {
+		e.nextAction = 60 + rand.Intn(120)
+		switch e.state {
+		case enemyStateSeek:
+			e.state = enemyStateOrbit
+		case enemyStateFlee:
+			e.state = enemyStateWander
+		}
+	}
+
+	switch e.state {
+	case enemyStateSeek:
+		a := angle(e.Position.X, e.Position.Y, player.position.X, player.position.Y)
+		e.Movement = clampSpeed(Vector{X: -math.Cos(a), Y: -math.Sin(a)})
+	case enemyStateFlee:
+		a := angle(e.Position.X, e.Position.Y, player.position.X, player.position.Y)
+		e.Movement = clampSpeed(Vector{X: math.Cos(a) * 2, Y: math.Sin(a) * 2})
+	case enemyStateOrbit:
+		// Circle the player, nudging in or out to settle on orbitRadius.
+		a := angle(e.Position.X, e.Position.Y, player.position.X, player.position.Y)
+		radial := (distToPlayer - orbitRadius) / orbitRadius
+		tangent := a + math.Pi/2
+		ox, oy := deltaXY(tangent, (minSpeed+maxSpeed)/2)
+		rx, ry := deltaXY(a, -radial)
+		e.Movement = clampSpeed(Vector{X: e.Movement.X*0.9 + (ox+rx)*0.1, Y: e.Movement.Y*0.9 + (oy+ry)*0.1})
+	}
+
+	return e.Meteor.Update()
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"image/color"
+	"log"
+	"math"
+)
+
+//go:embed keymap.json
+var keymapFS embed.FS
+
+// Keymap is the rebindable subset of keys Player cares about, loaded from
+// keymap.json. The file is embedded into the binary, so a remap takes
+// effect only after a rebuild.
+type Keymap struct {
+	Left  string `json:"left"`
+	Right string `json:"right"`
+	Shoot string `json:"shoot"`
+}
+
+var keyByName = map[string]ebiten.Key{
+	"A":          ebiten.KeyA,
+	"D":          ebiten.KeyD,
+	"Space":      ebiten.KeySpace,
+	"ArrowLeft":  ebiten.KeyArrowLeft,
+	"ArrowRight": ebiten.KeyArrowRight,
+}
+
+func loadKeymap() Keymap {
+	data, err := keymapFS.ReadFile("keymap.json")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var km Keymap
+	if err := json.Unmarshal(data, &km); err != nil {
+		log.Fatal(err)
+	}
+
+	return km
+}
+
+const gamepadDeadzone = 0.2
+
+// InputState is one tick's worth of player intent, gathered from whatever
+// mix of keyboard, gamepad, and touch the player is using. Player.Update
+// only ever reads from this, so it doesn't care which.
+type InputState struct {
+	RotateAxis float64 // -1 (left) .. 1 (right)
+	Shoot      bool
+}
+
+// InputSource polls every input method once per tick and merges them into
+// a single InputState.
+type InputSource struct {
+	keymap   Keymap
+	joystick *virtualJoystick
+}
+
+func NewInputSource() *InputSource {
+	return &InputSource{
+		keymap:   loadKeymap(),
+		joystick: newVirtualJoystick(),
+	}
+}
+
+// Poll gathers one tick's input given the current outside screenWidth and
+// screenHeight, which the touch controls are anchored relative to.
+func (s *InputSource) Poll(screenWidth, screenHeight int) InputState {
+	var in InputState
+
+	if ebiten.IsKeyPressed(keyByName[s.keymap.Left]) {
+		in.RotateAxis -= 1
+	}
+	if ebiten.IsKeyPressed(keyByName[s.keymap.Right]) {
+		in.RotateAxis += 1
+	}
+	if ebiten.IsKeyPressed(keyByName[s.keymap.Shoot]) {
+		in.Shoot = true
+	}
+
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		axis := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickHorizontal)
+		if math.Abs(axis) > gamepadDeadzone {
+			in.RotateAxis += axis
+		}
+		if ebiten.IsStandardGamepadButtonPressed(id, ebiten.StandardGamepadButtonRightBottom) {
+			in.Shoot = true
+		}
+	}
+
+	s.joystick.Update(screenHeight)
+	in.RotateAxis += s.joystick.RotateAxis()
+	if s.shootZoneTapped(screenWidth) {
+		in.Shoot = true
+	}
+
+	if in.RotateAxis < -1 {
+		in.RotateAxis = -1
+	}
+	if in.RotateAxis > 1 {
+		in.RotateAxis = 1
+	}
+
+	return in
+}
+
+// shootZoneTapped treats any touch on the right half of the screen, other
+// than the one driving the joystick, as a shoot input.
+func (s *InputSource) shootZoneTapped(screenWidth int) bool {
+	for _, id := range ebiten.AppendTouchIDs(nil) {
+		if id == s.joystick.touchID {
+			continue
+		}
+		x, _ := ebiten.TouchPosition(id)
+		if x > screenWidth/2 {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	joystickMarginX = 80
+	joystickMarginY = 80
+	joystickRadius  = 60
+	joystickCatchR  = joystickRadius * 2
+)
+
+// joystickCenter is the joystick's base position: a fixed margin from the
+// bottom-left corner of whatever screenHeight the canvas is currently
+// rendering at.
+func joystickCenter(screenHeight int) (int, int) {
+	return joystickMarginX, screenHeight - joystickMarginY
+}
+
+// virtualJoystick is a drag-based on-screen stick: touching down near its
+// center starts a stroke, and how far the stroke has dragged horizontally
+// (clamped to joystickRadius) becomes the rotation axis — the same stroke
+// pattern as the bottom-left stick in most touch shooters.
+type virtualJoystick struct {
+	touchID        ebiten.TouchID
+	active         bool
+	startX, startY int
+	currentX       int
+	currentY       int
+}
+
+func newVirtualJoystick() *virtualJoystick {
+	return &virtualJoystick{touchID: -1}
+}
+
+func (j *virtualJoystick) Update(screenHeight int) {
+	centerX, centerY := joystickCenter(screenHeight)
+
+	if !j.active {
+		for _, id := range inpututil.AppendJustPressedTouchIDs(nil) {
+			x, y := ebiten.TouchPosition(id)
+			if dist(x, y, centerX, centerY) <= joystickCatchR {
+				j.touchID = id
+				j.active = true
+				j.startX, j.startY = x, y
+				j.currentX, j.currentY = x, y
+				return
+			}
+		}
+		return
+	}
+
+	for _, id := range inpututil.AppendJustReleasedTouchIDs(nil) {
+		if id == j.touchID {
+			j.active = false
+			j.touchID = -1
+			return
+		}
+	}
+
+	j.currentX, j.currentY = ebiten.TouchPosition(j.touchID)
+}
+
+func (j *virtualJoystick) RotateAxis() float64 {
+	if !j.active {
+		return 0
+	}
+
+	axis := float64(j.currentX-j.startX) / joystickRadius
+	if axis < -1 {
+		axis = -1
+	}
+	if axis > 1 {
+		axis = 1
+	}
+	return axis
+}
+
+// Draw renders the joystick's base and, while it's being dragged, its
+// thumb offset toward the drag — purely visual feedback for touch players.
+func (j *virtualJoystick) Draw(screen *ebiten.Image) {
+	centerX, centerY := joystickCenter(screen.Bounds().Dy())
+
+	vector.StrokeCircle(screen, float32(centerX), float32(centerY), joystickRadius, 2, color.White, true)
+
+	thumbX, thumbY := float32(centerX), float32(centerY)
+	if j.active {
+		thumbX += float32(j.currentX - j.startX)
+		thumbY += float32(j.currentY - j.startY)
+	}
+	vector.DrawFilledCircle(screen, thumbX, thumbY, joystickRadius/3, color.White, true)
+}
+
+func dist(x1, y1, x2, y2 int) float64 {
+	dx, dy := float64(x1-x2), float64(y1-y2)
+	return math.Sqrt(dx*dx + dy*dy)
+}
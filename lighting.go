@@ -0,0 +1,71 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"image"
+	"image/color"
+	"math"
+)
+
+const torchRadius = 220
+
+// torchMask is a radial gradient, opaque at the center and fading to
+// transparent at torchRadius, used to punch a lit circle out of the
+// darkness overlay.
+var torchMask = newTorchMask(torchRadius)
+
+func newTorchMask(radius int) *ebiten.Image {
+	size := radius * 2
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx := float64(x-radius) + 0.5
+			dy := float64(y-radius) + 0.5
+			dist := math.Sqrt(dx*dx + dy*dy)
+
+			a := 1 - dist/float64(radius)
+			if a < 0 {
+				a = 0
+			}
+
+			img.Set(x, y, color.NRGBA{A: uint8(a * 255)})
+		}
+	}
+
+	return ebiten.NewImageFromImage(img)
+}
+
+// Game.minLevelColorScale and minPlayerColorScale control how dark the
+// arena gets away from, and right around, the player when fullBrightMode
+// is off.
+func (g *Game) drawLighting(screen *ebiten.Image) {
+	if g.fullBrightMode {
+		return
+	}
+
+	if g.overlayImg == nil || g.overlayImg.Bounds().Dx() != g.screenWidth || g.overlayImg.Bounds().Dy() != g.screenHeight {
+		g.overlayImg = ebiten.NewImage(g.screenWidth, g.screenHeight)
+	}
+	g.overlayImg.Clear()
+
+	ambient := g.minLevelColorScale
+	g.overlayImg.Fill(color.NRGBA{A: uint8((1 - ambient) * 255)})
+
+	if g.player.hasTorch {
+		bounds := g.player.sprite.Bounds()
+		cam := g.camGeoM()
+		playerScreenX, playerScreenY := cam.Apply(
+			g.player.position.X+float64(bounds.Dx())/2,
+			g.player.position.Y+float64(bounds.Dy())/2,
+		)
+
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(playerScreenX-torchRadius, playerScreenY-torchRadius)
+		op.CompositeMode = ebiten.CompositeModeDestinationOut
+		op.ColorScale.ScaleAlpha(float32(g.minPlayerColorScale))
+		g.overlayImg.DrawImage(torchMask, op)
+	}
+
+	screen.DrawImage(g.overlayImg, nil)
+}
@@ -2,8 +2,10 @@ package main
 
 import (
 	"embed"
+	"flag"
 	"fmt"
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/opentype"
@@ -129,16 +131,9 @@ type Meteor struct {
 	Sprite        *ebiten.Image
 }
 
-func NewMeteor() *Meteor {
-	// Figure out the target position — the screen center, in this case
-	target := Vector{
-		X: ScreenWidth / 2,
-		Y: ScreenHeight / 2,
-	}
-
-	// The distance from the center the meteor should spawn at — half the width
-	r := ScreenWidth / 2.0
-
+// NewMeteor spawns a meteor at distance r from target, at a random angle,
+// drifting back in toward target.
+func NewMeteor(target Vector, r float64) *Meteor {
 	// Pick a random angle — 2π is 360° — so this returns 0° to 360°
 	angle := rand.Float64() * 2 * math.Pi
 
@@ -195,7 +190,7 @@ func (m *Meteor) Update() error {
 	return nil
 }
 
-func (m *Meteor) Draw(screen *ebiten.Image) {
+func (m *Meteor) Draw(screen *ebiten.Image, cam ebiten.GeoM) {
 	bounds := m.Sprite.Bounds()
 	halfW := float64(bounds.Dx()) / 2
 	halfH := float64(bounds.Dy()) / 2
@@ -206,6 +201,7 @@ func (m *Meteor) Draw(screen *ebiten.Image) {
 	op.GeoM.Translate(halfW, halfH)
 
 	op.GeoM.Translate(m.Position.X, m.Position.Y)
+	op.GeoM.Concat(cam)
 
 	screen.DrawImage(m.Sprite, op)
 }
@@ -216,9 +212,16 @@ type Bullet struct {
 	position Vector
 	rotation float64
 	sprite   *ebiten.Image
+	speed    float64
+	damage   int
+	homing   bool
 }
 
 func NewBullet(pos Vector, rot float64) *Bullet {
+	return newBullet(pos, rot, 350, 1, false)
+}
+
+func newBullet(pos Vector, rot float64, speed float64, damage int, homing bool) *Bullet {
 	sprite := BulletSprite
 
 	bounds := sprite.Bounds()
@@ -232,6 +235,9 @@ func NewBullet(pos Vector, rot float64) *Bullet {
 		position: pos,
 		rotation: rot,
 		sprite:   sprite,
+		speed:    speed,
+		damage:   damage,
+		homing:   homing,
 	}
 }
 
@@ -246,8 +252,14 @@ func (b *Bullet) Collider() Rect {
 	}
 }
 
-func (b *Bullet) Update() error {
-	speed := 350 / float64(ebiten.TPS())
+func (b *Bullet) Update(enemies []*Enemy) error {
+	if b.homing {
+		if target := nearestEnemy(b.position, enemies); target != nil {
+			b.rotation = angle(target.Position.X, target.Position.Y, b.position.X, b.position.Y) + math.Pi/2
+		}
+	}
+
+	speed := b.speed / float64(ebiten.TPS())
 
 	b.position.X += math.Sin(b.rotation) * speed
 	b.position.Y += math.Cos(b.rotation) * -speed
@@ -255,7 +267,25 @@ func (b *Bullet) Update() error {
 	return nil
 }
 
-func (b *Bullet) Draw(screen *ebiten.Image) {
+// nearestEnemy returns the enemy closest to pos, or nil if enemies is empty.
+func nearestEnemy(pos Vector, enemies []*Enemy) *Enemy {
+	var nearest *Enemy
+	var nearestDist float64
+
+	for _, e := range enemies {
+		dx := e.Position.X - pos.X
+		dy := e.Position.Y - pos.Y
+		dist := dx*dx + dy*dy
+		if nearest == nil || dist < nearestDist {
+			nearest = e
+			nearestDist = dist
+		}
+	}
+
+	return nearest
+}
+
+func (b *Bullet) Draw(screen *ebiten.Image, cam ebiten.GeoM) {
 	bounds := b.sprite.Bounds()
 	halfW := float64(bounds.Dx()) / 2
 	halfH := float64(bounds.Dy()) / 2
@@ -266,6 +296,7 @@ func (b *Bullet) Draw(screen *ebiten.Image) {
 	op.GeoM.Translate(halfW, halfH)
 
 	op.GeoM.Translate(b.position.X, b.position.Y)
+	op.GeoM.Concat(cam)
 
 	screen.DrawImage(b.sprite, op)
 }
@@ -274,15 +305,23 @@ type BulletAdder interface {
 	AddBullet(b *Bullet)
 }
 
+type SoundPlayer interface {
+	PlaySound(name string)
+}
+
 type Player struct {
 	position      Vector
 	rotation      float64
 	shootCooldown *Timer
 	sprite        *ebiten.Image
 	bulletAdder   BulletAdder
+	sounds        SoundPlayer
+	weapon        Weapon
+	hasTorch      bool
+	torchTimer    *Timer
 }
 
-func NewPlayer(bulletAdder BulletAdder) *Player {
+func NewPlayer(bulletAdder BulletAdder, sounds SoundPlayer) *Player {
 	sprite := PlayerSprite
 
 	bounds := sprite.Bounds()
@@ -294,13 +333,32 @@ func NewPlayer(bulletAdder BulletAdder) *Player {
 		Y: ScreenHeight/2 - halfH,
 	}
 
-	return &Player{
-		position:      pos,
-		rotation:      0,
-		shootCooldown: NewTimer(1 * time.Second),
-		sprite:        sprite,
-		bulletAdder:   bulletAdder,
+	p := &Player{
+		position:    pos,
+		rotation:    0,
+		sprite:      sprite,
+		bulletAdder: bulletAdder,
+		sounds:      sounds,
 	}
+	p.SetWeapon(LaserWeapon{})
+
+	return p
+}
+
+// SetWeapon swaps the player's active weapon, resetting the shoot cooldown
+// to match the new weapon's rate of fire.
+func (p *Player) SetWeapon(w Weapon) {
+	p.weapon = w
+	p.shootCooldown = NewTimer(w.Cooldown())
+}
+
+const torchDuration = 15 * time.Second
+
+// GrantTorch turns the torch on for torchDuration, refreshing the timer if
+// the player already has one.
+func (p *Player) GrantTorch() {
+	p.hasTorch = true
+	p.torchTimer = NewTimer(torchDuration)
 }
 
 func (p *Player) Collider() Rect {
@@ -314,18 +372,13 @@ func (p *Player) Collider() Rect {
 	}
 }
 
-func (p *Player) Update() error {
+func (p *Player) Update(in InputState) error {
 	speed := math.Pi / float64(ebiten.TPS())
 
-	if ebiten.IsKeyPressed(ebiten.KeyA) {
-		p.rotation -= speed
-	}
-	if ebiten.IsKeyPressed(ebiten.KeyD) {
-		p.rotation += speed
-	}
+	p.rotation += in.RotateAxis * speed
 
 	p.shootCooldown.Update()
-	if p.shootCooldown.IsReady() && ebiten.IsKeyPressed(ebiten.KeySpace) {
+	if p.shootCooldown.IsReady() && in.Shoot {
 		p.shootCooldown.Reset()
 
 		bulletSpawnOffset := 50.0
@@ -339,15 +392,23 @@ func (p *Player) Update() error {
 			p.position.Y + halfH + math.Cos(p.rotation)*-bulletSpawnOffset,
 		}
 
-		b := NewBullet(spawnPos, p.rotation)
+		for _, b := range p.weapon.Fire(spawnPos, p.rotation) {
+			p.bulletAdder.AddBullet(b)
+		}
+		p.sounds.PlaySound("gunshot")
+	}
 
-		p.bulletAdder.AddBullet(b)
+	if p.hasTorch {
+		p.torchTimer.Update()
+		if p.torchTimer.IsReady() {
+			p.hasTorch = false
+		}
 	}
 
 	return nil
 }
 
-func (p *Player) Draw(screen *ebiten.Image) {
+func (p *Player) Draw(screen *ebiten.Image, cam ebiten.GeoM) {
 	bounds := p.sprite.Bounds()
 	halfW := float64(bounds.Dx()) / 2
 	halfH := float64(bounds.Dy()) / 2
@@ -358,6 +419,7 @@ func (p *Player) Draw(screen *ebiten.Image) {
 	op.GeoM.Translate(halfW, halfH)
 
 	op.GeoM.Translate(p.position.X, p.position.Y)
+	op.GeoM.Concat(cam)
 
 	screen.DrawImage(p.sprite, op)
 }
@@ -397,16 +459,52 @@ type Game struct {
 	player           *Player
 	score            int
 	meteorSpawnTimer *Timer
-	meteors          []*Meteor
+	pickupSpawnTimer *Timer
+	torchSpawnTimer  *Timer
+	enemies          []*Enemy
 	bullets          []*Bullet
+	pickups          []*Pickup
+	history          *History
+	collisionSpace   *CollisionSpace
+	sound            *SoundManager
+	input            *InputSource
+
+	overlayImg          *ebiten.Image
+	minLevelColorScale  float64
+	minPlayerColorScale float64
+	fullBrightMode      bool
+
+	camScale   float64
+	camScaleTo float64
+	camOffset  Vector
+	mousePanX  int
+	mousePanY  int
+
+	// screenWidth/screenHeight track the outside size Layout was last given,
+	// defaulting to ScreenWidth/ScreenHeight until the first Layout call.
+	screenWidth  int
+	screenHeight int
 }
 
 func (g *Game) AddBullet(b *Bullet) {
 	g.bullets = append(g.bullets, b)
 }
 
+func (g *Game) PlaySound(name string) {
+	g.sound.Play(name)
+}
+
 func (g *Game) Update() error {
-	err := g.player.Update()
+	if ebiten.IsKeyPressed(ebiten.KeyR) {
+		if s, ok := g.history.Pop(); ok {
+			g.restore(s)
+		}
+		return nil
+	}
+
+	g.cameraUpdate()
+
+	err := g.player.Update(g.input.Poll(g.screenWidth, g.screenHeight))
 	if err != nil {
 		return err
 	}
@@ -415,81 +513,204 @@ func (g *Game) Update() error {
 	if g.meteorSpawnTimer.IsReady() {
 		g.meteorSpawnTimer.Reset()
 
-		m := NewMeteor()
-		g.meteors = append(g.meteors, m)
+		frustumHalfW, frustumHalfH := g.camFrustum()
+		spawnRadius := math.Hypot(frustumHalfW, frustumHalfH) + 100
+
+		e := NewEnemy(g.camCenter(), spawnRadius)
+		g.enemies = append(g.enemies, e)
 	}
 
-	for _, m := range g.meteors {
-		err = m.Update()
+	for _, e := range g.enemies {
+		err = e.Update(g.player, g.bullets)
 		if err != nil {
 			return err
 		}
 	}
 
 	for _, b := range g.bullets {
-		err = b.Update()
+		err = b.Update(g.enemies)
 		if err != nil {
 			return err
 		}
 	}
 
-	for i, m := range g.meteors {
-		for j, b := range g.bullets {
-			if m.Collider().Intersects(b.Collider()) {
-				// A meteor collided with a bullet
-				g.meteors = append(g.meteors[:i], g.meteors[i+1:]...)
-				g.bullets = append(g.bullets[:j], g.bullets[j+1:]...)
+	enemyEntities := make([]Entity, len(g.enemies))
+	for i, e := range g.enemies {
+		enemyEntities[i] = e
+	}
+	g.collisionSpace.Rebuild(enemyEntities)
+
+	// Collect hits first and apply them after both loops are done, rather
+	// than mutating g.enemies/g.bullets mid-range — mutating a slice while
+	// ranging over it either skips the element shifted into the current
+	// index or revisits one twice.
+	deadBullets := make(map[int]bool)
+	deadEnemies := make(map[*Enemy]bool)
 
-				// Increase the score
+	for j, b := range g.bullets {
+		for _, ent := range g.collisionSpace.Query(b.Collider()) {
+			e := ent.(*Enemy)
+			if deadEnemies[e] || !e.Collider().Intersects(b.Collider()) {
+				continue
+			}
+
+			// A bullet always disappears on impact, but the enemy only
+			// dies once its hp has been whittled down.
+			deadBullets[j] = true
+			e.hp -= b.damage
+			if e.hp <= 0 {
+				deadEnemies[e] = true
 				g.score++
+				g.PlaySound("explosion")
+			}
+			break
+		}
+	}
+
+	if len(deadBullets) > 0 {
+		bullets := g.bullets[:0]
+		for j, b := range g.bullets {
+			if !deadBullets[j] {
+				bullets = append(bullets, b)
 			}
 		}
+		g.bullets = bullets
 	}
 
-	for _, m := range g.meteors {
-		if m.Collider().Intersects(g.player.Collider()) {
-			// A meteor collided with the player
+	if len(deadEnemies) > 0 {
+		enemies := g.enemies[:0]
+		for _, e := range g.enemies {
+			if !deadEnemies[e] {
+				enemies = append(enemies, e)
+			}
+		}
+		g.enemies = enemies
+
+		enemyEntities = enemyEntities[:0]
+		for _, e := range g.enemies {
+			enemyEntities = append(enemyEntities, e)
+		}
+		g.collisionSpace.Rebuild(enemyEntities)
+	}
+
+	for _, ent := range g.collisionSpace.Query(g.player.Collider()) {
+		if ent.(*Enemy).Collider().Intersects(g.player.Collider()) {
+			// An enemy collided with the player
+			g.PlaySound("death")
 			g.Reset()
+			break
 		}
 	}
 
+	g.pickupSpawnTimer.Update()
+	if g.pickupSpawnTimer.IsReady() {
+		g.pickupSpawnTimer.Reset()
+
+		frustumHalfW, frustumHalfH := g.camFrustum()
+		g.pickups = append(g.pickups, NewPickup(g.camCenter(), frustumHalfW, frustumHalfH))
+	}
+
+	g.torchSpawnTimer.Update()
+	if g.torchSpawnTimer.IsReady() {
+		g.torchSpawnTimer.Reset()
+
+		frustumHalfW, frustumHalfH := g.camFrustum()
+		g.pickups = append(g.pickups, NewTorchPickup(g.camCenter(), frustumHalfW, frustumHalfH))
+	}
+
+	for i, pu := range g.pickups {
+		if pu.Collider().Intersects(g.player.Collider()) {
+			if pu.torch {
+				g.player.GrantTorch()
+			} else {
+				g.player.SetWeapon(pu.weapon)
+			}
+			g.pickups = append(g.pickups[:i], g.pickups[i+1:]...)
+			break
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyF) {
+		g.fullBrightMode = !g.fullBrightMode
+	}
+
+	g.history.Push(g.snapshot())
+
 	return nil
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
-	g.player.Draw(screen)
+	cam := g.camGeoM()
 
-	for _, m := range g.meteors {
-		m.Draw(screen)
+	g.drawBackground(screen, cam)
+
+	g.player.Draw(screen, cam)
+
+	for _, e := range g.enemies {
+		e.Draw(screen, cam)
 	}
 
 	for _, b := range g.bullets {
-		b.Draw(screen)
+		b.Draw(screen, cam)
 	}
 
-	text.Draw(screen, fmt.Sprintf("%06d", g.score), ScoreFont, ScreenWidth/2-100, 50, color.White)
+	for _, pu := range g.pickups {
+		pu.Draw(screen, cam)
+	}
+
+	g.drawLighting(screen)
+
+	g.input.joystick.Draw(screen)
+
+	text.Draw(screen, fmt.Sprintf("%06d", g.score), ScoreFont, g.screenWidth/2-100, 50, color.White)
 }
 
-func (g *Game) Layout(outsideWith, outsideHeight int) (screenWidth, screenHeight int) {
-	return ScreenWidth, ScreenHeight
+// Layout renders at whatever outside size the window or browser actually
+// gives us, so the world stays crisp at any resolution; zooming the world
+// itself is camScale's job, independent of this.
+func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	g.screenWidth, g.screenHeight = outsideWidth, outsideHeight
+	return outsideWidth, outsideHeight
 }
 
 func (g *Game) Reset() {
-	g.player = NewPlayer(g)
-	g.meteors = nil
+	g.player = NewPlayer(g, g)
+	g.enemies = nil
 	g.bullets = nil
+	g.pickups = nil
 	g.score = 0
 }
 
 func main() {
+	flag.Parse()
 
-	g := &Game{
-		meteorSpawnTimer: NewTimer(5 * time.Second),
-		meteors:          nil,
-		bullets:          nil,
-	}
+	sound := NewSoundManager()
+	sound.LoadWAV("gunshot", "assets/audio/gunshot.wav")
+	sound.LoadWAV("explosion", "assets/audio/explosion.wav")
+	sound.LoadWAV("death", "assets/audio/death.wav")
+	sound.LoadMP3("theme", "assets/audio/theme.mp3")
 
-	g.player = NewPlayer(g)
+	g := &Game{
+		meteorSpawnTimer:    NewTimer(5 * time.Second),
+		pickupSpawnTimer:    NewTimer(10 * time.Second),
+		torchSpawnTimer:     NewTimer(20 * time.Second),
+		enemies:             nil,
+		bullets:             nil,
+		history:             NewHistory(ebiten.TPS() * rewindSeconds),
+		minLevelColorScale:  0.08,
+		minPlayerColorScale: 1.0,
+		camScale:            1.0,
+		camScaleTo:          1.0,
+		screenWidth:         ScreenWidth,
+		screenHeight:        ScreenHeight,
+		collisionSpace:      NewCollisionSpace(256),
+		sound:               sound,
+		input:               NewInputSource(),
+	}
+
+	g.player = NewPlayer(g, g)
+
+	sound.PlayMusic("theme")
 
 	err := ebiten.RunGame(g)
 	if err != nil {
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"math/rand"
+)
+
+var PickupSprite = mustLoadImage("assets/pickups/weapon.png")
+var TorchPickupSprite = mustLoadImage("assets/pickups/torch.png")
+
+// pickupWeapons are the weapons a Pickup can grant; the starting laser is
+// deliberately excluded since it's already the default.
+var pickupWeapons = []Weapon{
+	UziWeapon{},
+	ShotgunWeapon{},
+	HomingMissileWeapon{},
+}
+
+// Pickup sits in the arena until its collider overlaps the player's, then either
+// swaps the player's active weapon or grants a temporary torch, depending
+// on which it was constructed as.
+type Pickup struct {
+	position Vector
+	sprite   *ebiten.Image
+	weapon   Weapon // nil for a torch pickup
+	torch    bool
+}
+
+func NewPickup(center Vector, halfW, halfH float64) *Pickup {
+	return &Pickup{
+		position: randomPickupPosition(center, halfW, halfH),
+		sprite:   PickupSprite,
+		weapon:   pickupWeapons[rand.Intn(len(pickupWeapons))],
+	}
+}
+
+func NewTorchPickup(center Vector, halfW, halfH float64) *Pickup {
+	return &Pickup{
+		position: randomPickupPosition(center, halfW, halfH),
+		sprite:   TorchPickupSprite,
+		torch:    true,
+	}
+}
+
+// randomPickupPosition picks a point somewhere within the camera frustum
+// centered on center, so pickups always spawn where the player can see them.
+func randomPickupPosition(center Vector, halfW, halfH float64) Vector {
+	return Vector{
+		X: center.X + (rand.Float64()*2-1)*halfW,
+		Y: center.Y + (rand.Float64()*2-1)*halfH,
+	}
+}
+
+func (p *Pickup) Collider() Rect {
+	bounds := p.sprite.Bounds()
+
+	return Rect{
+		X:      p.position.X,
+		Y:      p.position.Y,
+		Width:  float64(bounds.Dx()),
+		Height: float64(bounds.Dy()),
+	}
+}
+
+func (p *Pickup) Draw(screen *ebiten.Image, cam ebiten.GeoM) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(p.position.X, p.position.Y)
+	op.GeoM.Concat(cam)
+
+	screen.DrawImage(p.sprite, op)
+}
@@ -0,0 +1,164 @@
+package main
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// rewindSeconds is how far back in time the player can rewind.
+const rewindSeconds = 5
+
+// playerSnapshot captures the parts of Player that change every tick.
+type playerSnapshot struct {
+	position Vector
+	rotation float64
+}
+
+func (p *Player) Snapshot() playerSnapshot {
+	return playerSnapshot{
+		position: p.position,
+		rotation: p.rotation,
+	}
+}
+
+func (p *Player) Restore(s playerSnapshot) {
+	p.position = s.position
+	p.rotation = s.rotation
+}
+
+// enemySnapshot captures the parts of Enemy that change every tick.
+type enemySnapshot struct {
+	position   Vector
+	movement   Vector
+	rotation   float64
+	sprite     *ebiten.Image
+	state      enemyState
+	nextAction int
+	hp         int
+}
+
+func (e *Enemy) Snapshot() enemySnapshot {
+	return enemySnapshot{
+		position:   e.Position,
+		movement:   e.Movement,
+		rotation:   e.Rotation,
+		sprite:     e.Sprite,
+		state:      e.state,
+		nextAction: e.nextAction,
+		hp:         e.hp,
+	}
+}
+
+func (e *Enemy) Restore(s enemySnapshot) {
+	e.Position = s.position
+	e.Movement = s.movement
+	e.Rotation = s.rotation
+	e.Sprite = s.sprite
+	e.state = s.state
+	e.nextAction = s.nextAction
+	e.hp = s.hp
+}
+
+// bulletSnapshot captures the parts of Bullet that change every tick.
+type bulletSnapshot struct {
+	position Vector
+	rotation float64
+	speed    float64
+	damage   int
+	homing   bool
+}
+
+func (b *Bullet) Snapshot() bulletSnapshot {
+	return bulletSnapshot{
+		position: b.position,
+		rotation: b.rotation,
+		speed:    b.speed,
+		damage:   b.damage,
+		homing:   b.homing,
+	}
+}
+
+func (b *Bullet) Restore(s bulletSnapshot) {
+	b.position = s.position
+	b.rotation = s.rotation
+	b.speed = s.speed
+	b.damage = s.damage
+	b.homing = s.homing
+}
+
+// gameSnapshot is one frame of rewindable state for the whole scene.
+type gameSnapshot struct {
+	player  playerSnapshot
+	enemies []enemySnapshot
+	bullets []bulletSnapshot
+	score   int
+}
+
+// History is a ring buffer of gameSnapshots covering the last rewindSeconds
+// of play. It survives Game.Reset, so a player can rewind past their own
+// death.
+type History struct {
+	frames []gameSnapshot
+	head   int
+	count  int
+}
+
+func NewHistory(size int) *History {
+	return &History{
+		frames: make([]gameSnapshot, size),
+	}
+}
+
+func (h *History) Push(s gameSnapshot) {
+	h.frames[h.head] = s
+	h.head = (h.head + 1) % len(h.frames)
+	if h.count < len(h.frames) {
+		h.count++
+	}
+}
+
+// Pop removes and returns the most recently pushed snapshot.
+func (h *History) Pop() (gameSnapshot, bool) {
+	if h.count == 0 {
+		return gameSnapshot{}, false
+	}
+	h.head = (h.head - 1 + len(h.frames)) % len(h.frames)
+	h.count--
+	return h.frames[h.head], true
+}
+
+func (g *Game) snapshot() gameSnapshot {
+	enemies := make([]enemySnapshot, len(g.enemies))
+	for i, e := range g.enemies {
+		enemies[i] = e.Snapshot()
+	}
+
+	bullets := make([]bulletSnapshot, len(g.bullets))
+	for i, b := range g.bullets {
+		bullets[i] = b.Snapshot()
+	}
+
+	return gameSnapshot{
+		player:  g.player.Snapshot(),
+		enemies: enemies,
+		bullets: bullets,
+		score:   g.score,
+	}
+}
+
+// restore rebuilds g.enemies and g.bullets from a snapshot.
+func (g *Game) restore(s gameSnapshot) {
+	g.player.Restore(s.player)
+	g.score = s.score
+
+	g.enemies = make([]*Enemy, len(s.enemies))
+	for i, es := range s.enemies {
+		e := NewEnemy(Vector{}, 0)
+		e.Restore(es)
+		g.enemies[i] = e
+	}
+
+	g.bullets = make([]*Bullet, len(s.bullets))
+	for i, bs := range s.bullets {
+		b := NewBullet(Vector{}, 0)
+		b.Restore(bs)
+		g.bullets[i] = b
+	}
+}
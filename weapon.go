@@ -0,0 +1,69 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// Weapon knows its own cooldown and how to turn a muzzle position and
+// facing angle into the bullets it fires.
+type Weapon interface {
+	Cooldown() time.Duration
+	Fire(pos Vector, rot float64) []*Bullet
+}
+
+// LaserWeapon is the original single-shot weapon the player starts with.
+type LaserWeapon struct{}
+
+func (LaserWeapon) Cooldown() time.Duration {
+	return 1 * time.Second
+}
+
+func (LaserWeapon) Fire(pos Vector, rot float64) []*Bullet {
+	return []*Bullet{newBullet(pos, rot, 350, 1, false)}
+}
+
+// UziWeapon fires rapidly but each bullet only does one point of damage.
+type UziWeapon struct{}
+
+func (UziWeapon) Cooldown() time.Duration {
+	return 100 * time.Millisecond
+}
+
+func (UziWeapon) Fire(pos Vector, rot float64) []*Bullet {
+	return []*Bullet{newBullet(pos, rot, 450, 1, false)}
+}
+
+// ShotgunWeapon fires a spread of pellets at once.
+type ShotgunWeapon struct{}
+
+func (ShotgunWeapon) Cooldown() time.Duration {
+	return 800 * time.Millisecond
+}
+
+const shotgunPellets = 5
+const shotgunSpread = math.Pi / 8
+
+func (ShotgunWeapon) Fire(pos Vector, rot float64) []*Bullet {
+	bullets := make([]*Bullet, shotgunPellets)
+
+	for i := range bullets {
+		// Spread the pellets evenly across shotgunSpread, centered on rot.
+		offset := shotgunSpread*(float64(i)/float64(shotgunPellets-1)) - shotgunSpread/2
+		bullets[i] = newBullet(pos, rot+offset, 300, 1, false)
+	}
+
+	return bullets
+}
+
+// HomingMissileWeapon fires a single slow missile that steers toward the
+// nearest enemy every tick.
+type HomingMissileWeapon struct{}
+
+func (HomingMissileWeapon) Cooldown() time.Duration {
+	return 2 * time.Second
+}
+
+func (HomingMissileWeapon) Fire(pos Vector, rot float64) []*Bullet {
+	return []*Bullet{newBullet(pos, rot, 200, 3, true)}
+}